@@ -0,0 +1,29 @@
+package gogrib2
+
+import (
+	"errors"
+
+	"github.com/sdifrance/gogrib2/internal"
+)
+
+// Errors returned, wrapped, by Read and ScanHeaders. Use errors.Is to check for them.
+var (
+	// ErrNotGRIB is returned when a message does not start with 'GRIB'
+	ErrNotGRIB = errors.New("not a GRIB message")
+	// ErrUnsupportedEdition is returned for messages of GRIB edition other than 2
+	ErrUnsupportedEdition = errors.New("unsupported GRIB edition")
+	// ErrTruncated is returned when a message or section runs past the end of data
+	ErrTruncated = errors.New("truncated GRIB data")
+
+	// ErrUnsupportedTemplate is returned when a message uses a grid definition,
+	// product definition or data representation template that is not implemented
+	ErrUnsupportedTemplate = internal.ErrUnsupportedTemplate
+	// ErrUnsupportedGrid is ErrUnsupportedTemplate for grid definition templates
+	ErrUnsupportedGrid = internal.ErrUnsupportedGrid
+	// ErrUnsupportedPacking is ErrUnsupportedTemplate for data representation templates
+	ErrUnsupportedPacking = internal.ErrUnsupportedPacking
+)
+
+// PointCountError is returned when the number of packed values in a message
+// does not match its grid point count less the points masked by the bitmap
+type PointCountError = internal.PointCountError
@@ -0,0 +1,51 @@
+package gogrib2
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+	edition1 := append([]byte{}, msg...)
+	edition1[7] = 1
+
+	tests := []struct {
+		name string
+		data []byte
+		want []error
+		// scan is true if ScanHeaders returns the error as well
+		scan bool
+	}{
+		{"not grib", append([]byte("GRIP"), msg[4:]...), []error{ErrNotGRIB}, true},
+		{"edition 1", edition1, []error{ErrUnsupportedEdition}, true},
+		{"grid template 3.99", withTemplate(msg, 3, 99),
+			[]error{ErrUnsupportedGrid, ErrUnsupportedTemplate}, false},
+		{"packing template 5.51", withTemplate(msg, 5, 51),
+			[]error{ErrUnsupportedPacking, ErrUnsupportedTemplate}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(tt.data)
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("Read: got %v, want %v", err, want)
+				}
+			}
+			if !tt.scan {
+				return
+			}
+			_, err = ScanHeaders(bytes.NewReader(tt.data))
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("ScanHeaders: got %v, want %v", err, want)
+				}
+			}
+		})
+	}
+
+	if errors.Is(ErrUnsupportedGrid, ErrUnsupportedPacking) {
+		t.Error("ErrUnsupportedGrid matches ErrUnsupportedPacking")
+	}
+}
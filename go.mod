@@ -1,5 +1,3 @@
 module github.com/sdifrance/gogrib2
 
 go 1.15
-
-require github.com/pkg/errors v0.9.1 // indirect
@@ -0,0 +1,638 @@
+package gogrib2
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+type point struct {
+	lat, lon float64
+	value    float32
+}
+
+func points(values []Value) []point {
+	p := make([]point, len(values))
+	for i, v := range values {
+		p[i] = point{v.Latitude, v.Longitude, v.Value}
+	}
+	return p
+}
+
+func equalPoints(a, b []point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReadCorruptSection7(t *testing.T) {
+	tests := []struct {
+		name  string
+		field testField
+	}{
+		{"simple packing", testField{surface: 100, values: []byte{1, 2}}},
+		{"ieee", testField{surface: 100, ieee: true, values: []byte{1, 2}}},
+		{"ieee with bitmap", testField{surface: 100, ieee: true,
+			bitmap: []bool{true, true, true, false, true, true}, values: []byte{1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := testMessage{ni: 3, nj: 2, fields: []testField{tt.field}}.bytes()
+			if _, err := Read(msg); err == nil {
+				t.Fatal("expected error for short section 7")
+			}
+		})
+	}
+}
+
+func TestReadPointCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		bitmap []bool
+		count  uint32
+		want   int
+	}{
+		{"fewer", nil, 5, 6},
+		{"more", nil, 7, 6},
+		{"bitmap", []bool{true, true, false, true, true, true}, 6, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := testMessage{ni: 3, nj: 2, fields: []testField{
+				{surface: 100, bitmap: tt.bitmap, values: []byte{0, 1, 2, 3, 4, 5, 6}},
+			}}.bytes()
+			binary.BigEndian.PutUint32(msg[sectionOffset(msg, 5)+5:], tt.count)
+
+			_, err := Read(msg)
+			var pe *PointCountError
+			if !errors.As(err, &pe) {
+				t.Fatalf("got %v, want PointCountError", err)
+			}
+			if pe.Expected != tt.want || pe.Actual != int(tt.count) {
+				t.Errorf("got expected %d and actual %d, want %d and %d",
+					pe.Expected, pe.Actual, tt.want, tt.count)
+			}
+		})
+	}
+}
+
+func TestReadShortTemplate(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+	ieee := testMessage{ni: 3, nj: 2, fields: []testField{{surface: 100, ieee: true}}}.bytes()
+
+	spaceView := make([]byte, 72-5)
+	copy(spaceView[1:], u32(6))
+	copy(spaceView[7:], u16(90))
+	polar := testMessage{ni: 3, nj: 2, sec3: polarSection(3, 2)}.bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"section 3 header only", shortenSection(msg, 3, 14)},
+		{"template 3.0", shortenSection(msg, 3, 71)},
+		{"template 3.20", shortenSection(polar, 3, 64)},
+		{"template 3.90", testMessage{ni: 3, nj: 2, sec3: spaceView}.bytes()},
+		{"section 4 header only", shortenSection(msg, 4, 9)},
+		{"section 4 without parameter", shortenSection(withTemplate(msg, 4, 99), 4, 10)},
+		{"template 4.0", shortenSection(msg, 4, 33)},
+		// section 4 of msg is 34 octets, count of 4.34 and 4.57 reads 100
+		// and 24576 from the template 4.0 octets
+		{"template 4.9", withTemplate(msg, 4, 9)},
+		{"template 4.34", withTemplate(msg, 4, 34)},
+		{"template 4.44", withTemplate(msg, 4, 44)},
+		{"template 4.46", withTemplate(msg, 4, 46)},
+		{"template 4.47", withTemplate(msg, 4, 47)},
+		{"template 4.48", withTemplate(msg, 4, 48)},
+		{"template 4.57", withTemplate(msg, 4, 57)},
+		{"section 5 header only", shortenSection(msg, 5, 11)},
+		{"template 5.0", shortenSection(msg, 5, 20)},
+		{"template 5.4", shortenSection(ieee, 5, 11)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(tt.data); err == nil {
+				t.Error("Read: expected error")
+			}
+			if _, err := ScanHeaders(bytes.NewReader(tt.data)); err == nil {
+				t.Error("ScanHeaders: expected error")
+			}
+		})
+	}
+}
+
+func TestReadPolarStereographic(t *testing.T) {
+	g, err := Read(testMessage{ni: 3, nj: 2, sec3: polarSection(3, 2)}.bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g) != 1 || len(g[0].Values) != 6 {
+		t.Fatalf("got %d fields", len(g))
+	}
+	if v := g[0].Values[0]; math.Abs(v.Latitude-60) > 1e-3 || math.Abs(v.Longitude-250) > 1e-3 {
+		t.Errorf("first point at %v, %v, want 60, 250", v.Latitude, v.Longitude)
+	}
+}
+
+func TestReadScanMode(t *testing.T) {
+	// values 0 to 5 are packed in the order of each scanning mode,
+	// rows are the packed values of Grid2D from north to south
+	tests := []struct {
+		scan byte
+		rows [][]float32
+	}{
+		{0, [][]float32{{0, 1, 2}, {3, 4, 5}}},
+		{64, [][]float32{{3, 4, 5}, {0, 1, 2}}},
+		{128, [][]float32{{2, 1, 0}, {5, 4, 3}}},
+		{32, [][]float32{{0, 2, 4}, {1, 3, 5}}},
+		{16, [][]float32{{0, 1, 2}, {5, 4, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("scan %d", tt.scan), func(t *testing.T) {
+			g, err := Read(testMessage{ni: 3, nj: 2, scan: tt.scan}.bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Values are WE:SN, each point keeps the value packed for it
+			var want []point
+			for j, lat := range []float64{49, 50} {
+				for i, lon := range []float64{10, 11, 12} {
+					want = append(want, point{lat, lon, 273 + tt.rows[1-j][i]})
+				}
+			}
+			if got := points(g[0].Values); !equalPoints(got, want) {
+				t.Errorf("values are %v, want %v", got, want)
+			}
+
+			rows, err := g[0].Grid2D()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for j := range tt.rows {
+				for i := range tt.rows[j] {
+					if rows[j][i] != 273+tt.rows[j][i] {
+						t.Fatalf("rows are %v, want %v + 273", rows, tt.rows)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGrid2DUnsupported(t *testing.T) {
+	staggered, err := Read(testMessage{ni: 3, nj: 2, scan: 8}.bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	thinned := GRIB2{grid: GRIB2Grid{Ni: -1, Nj: 2}, Values: make([]Value, 6)}
+
+	for name, g := range map[string]GRIB2{"staggered": staggered[0], "thinned": thinned} {
+		if _, err := g.Grid2D(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestReadSpaceView(t *testing.T) {
+	const n, nr = 5, 6.6107
+	g, err := Read(testMessage{ni: n, nj: n, sec3: spaceViewSection(n, 10000000, 4)}.bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := g[0].Values
+	if len(values) != n*n {
+		t.Fatalf("got %d values, want %d", len(values), n*n)
+	}
+
+	// one pixel east of the sub-satellite point is seen at angle x from
+	// the satellite, on the equator at longitude 10 + atan(s2 / s1)
+	x := 2 * math.Asin(1/nr) / 4
+	sn := nr*math.Cos(x) - math.Sqrt(nr*nr*math.Cos(x)*math.Cos(x)-(nr*nr-1))
+	east := 10 + math.Atan(sn*math.Sin(x)/(nr-sn*math.Cos(x)))*180/math.Pi
+
+	tests := []struct {
+		name     string
+		i, j     int
+		lat, lon float64
+	}{
+		{"sub-satellite point", 2, 2, 0, 10},
+		{"east pixel", 3, 2, 0, east},
+		{"off disk", 0, 0, 9.999e20, 9.999e20},
+	}
+	for _, tt := range tests {
+		v := values[tt.i+tt.j*n]
+		if math.Abs(v.Latitude-tt.lat) > 1e-6 || math.Abs(v.Longitude-tt.lon) > 1e-6 {
+			t.Errorf("%s at %v, %v, want %v, %v", tt.name, v.Latitude, v.Longitude, tt.lat, tt.lon)
+		}
+	}
+	if v := values[2+3*n]; v.Latitude <= 0 || math.Abs(v.Longitude-10) > 1e-6 {
+		t.Errorf("north pixel at %v, %v, want north of 0, 10", v.Latitude, v.Longitude)
+	}
+}
+
+func TestReadContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	gribs, err := ReadContext(ctx, testMessage{ni: 3, nj: 2}.bytes())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if gribs != nil {
+		t.Errorf("got %d fields, want none", len(gribs))
+	}
+}
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  testMessage
+		want GRIB2Grid
+	}{
+		{"lat-lon", testMessage{ni: 3, nj: 2},
+			GRIB2Grid{0, 3, 2, 50, 10, 49, 12, 1, 1, 0}},
+		{"lat-lon south to north", testMessage{ni: 3, nj: 2, scan: 64},
+			GRIB2Grid{0, 3, 2, 49, 10, 50, 12, 1, 1, 64}},
+		{"mercator", testMessage{ni: 3, nj: 2, sec3: mercatorSection(3, 2)},
+			GRIB2Grid{10, 3, 2, 0, 10, 1, 12, 111200, 111200, 64}},
+		{"polar stereographic", testMessage{ni: 3, nj: 2, sec3: polarSection(3, 2)},
+			GRIB2Grid{20, 3, 2, 60, 250, 0, 0, 50000, 50000, 64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Read(tt.msg.bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := g[0].Grid(); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefTimeSignificance(t *testing.T) {
+	tests := []struct {
+		code byte
+		want RefTimeSignificance
+		name string
+	}{
+		{0, RefTimeAnalysis, "analysis"},
+		{1, RefTimeStartOfForecast, "start of forecast"},
+		{2, RefTimeVerifyingTime, "verifying time of forecast"},
+		{3, RefTimeObservation, "observation time"},
+		{4, 4, "reserved (4)"},
+		{191, 191, "reserved (191)"},
+		{255, RefTimeMissing, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := testMessage{ni: 3, nj: 2}.bytes()
+			msg[sectionOffset(msg, 1)+11] = tt.code
+
+			g, err := Read(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := g[0].RefTimeSignificance(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+			if got := tt.want.String(); got != tt.name {
+				t.Errorf("got %q, want %q", got, tt.name)
+			}
+		})
+	}
+}
+
+func TestReadBitmap(t *testing.T) {
+	// point 4 in scan order is masked: second, southern row, second column
+	bitmap := []bool{true, true, true, true, false, true}
+	msg := testMessage{ni: 3, nj: 2, fields: []testField{
+		{category: 2, number: 2, surface: 100, surfaceValue: 50000, bitmap: bitmap},
+		{category: 2, number: 3, surface: 100, surfaceValue: 50000, bitmap: bitmap, reuseBitmap: true},
+	}}.bytes()
+
+	gribs, err := Read(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gribs) != 2 {
+		t.Fatalf("got %d fields, want 2", len(gribs))
+	}
+
+	want := []point{
+		{49, 10, 276}, {49, 11, UndefinedValue}, {49, 12, 277},
+		{50, 10, 273}, {50, 11, 274}, {50, 12, 275},
+	}
+	for i, name := range []string{"UGRD", "VGRD"} {
+		g := gribs[i]
+		if g.Name != name {
+			t.Errorf("field %d is %s, want %s", i, g.Name, name)
+		}
+		if got := points(g.Values); !equalPoints(got, want) {
+			t.Errorf("%s values are %v, want %v", name, got, want)
+		}
+		rows, err := g.Grid2D()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if rows[0][0] != 273 || rows[1][1] != UndefinedValue {
+			t.Errorf("%s rows are %v", name, rows)
+		}
+	}
+}
+
+func TestReadBitmapNotDefined(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2, fields: []testField{
+		{surface: 100, bitmap: []bool{true, true, true, true, true, true}, reuseBitmap: true},
+	}}.bytes()
+	if _, err := Read(msg); err == nil {
+		t.Error("expected error for bitmap indicator 254 without previous bitmap")
+	}
+}
+
+// benchMessage is global 0.25 degree lat-lon grid of 1440x721 points
+var benchMessage = testMessage{ni: 1440, nj: 721,
+	sec3: latLonSection(1440, 721, 0, 90000000, 0, 250000)}.bytes()
+
+func BenchmarkRead(b *testing.B) {
+	b.SetBytes(int64(len(benchMessage)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Read(benchMessage); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadInto(b *testing.B) {
+	b.SetBytes(int64(len(benchMessage)))
+	b.ReportAllocs()
+	var gribs []GRIB2
+	for i := 0; i < b.N; i++ {
+		var err error
+		if gribs, err = ReadInto(gribs, benchMessage); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadInto(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2, fields: []testField{
+		{category: 2, number: 2, surface: 100, surfaceValue: 50000},
+		{category: 2, number: 3, surface: 100, surfaceValue: 50000, values: []byte{5, 4, 3, 2, 1, 0}},
+	}}.bytes()
+	want, err := Read(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := Read(testMessage{ni: 4, nj: 2}.bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst = append(dst, GRIB2{})
+	reused := &dst[0].Values[0]
+
+	got, err := ReadInto(dst, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || !equalPoints(points(got[i].Values), points(want[i].Values)) {
+			t.Errorf("field %d is %s %v, want %s %v", i, got[i].Name, points(got[i].Values),
+				want[i].Name, points(want[i].Values))
+		}
+	}
+	if &got[0].Values[0] != reused {
+		t.Error("Values of the first field are not reused")
+	}
+}
+
+func TestReadTrailingBytes(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+
+	tests := []struct {
+		name    string
+		tail    string
+		wantErr bool
+	}{
+		{"none", "", false},
+		{"newline", "\n", false},
+		{"zeros", "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", false},
+		{"whitespace", " \r\n\t", false},
+		{"garbage", "x", true},
+		{"partial message", "\nGRIB", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(append(append([]byte{}, msg...), msg...), tt.tail...)
+
+			gribs, err := Read(data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Read: got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && len(gribs) != 2 {
+				t.Errorf("Read: got %d fields, want 2", len(gribs))
+			}
+
+			headers, err := ScanHeaders(bytes.NewReader(data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ScanHeaders: got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && len(headers) != 2 {
+				t.Errorf("ScanHeaders: got %d headers, want 2", len(headers))
+			}
+		})
+	}
+}
+
+func TestSurface(t *testing.T) {
+	tests := []struct {
+		name  string
+		field testField
+		want  FixedSurface
+		level string
+	}{
+		{"isobaric", testField{surface: 100, surfaceValue: 50000},
+			FixedSurface{Type: 100, ScaledValue: 50000, Value: 50000, HasValue: true}, "500 mb"},
+		{"scaled", testField{surface: 100, surfaceScale: 2, surfaceValue: 85000},
+			FixedSurface{Type: 100, ScaleFactor: 2, ScaledValue: 85000, Value: 850, HasValue: true}, "8.5 mb"},
+		{"height", testField{surface: 103, surfaceValue: 2},
+			FixedSurface{Type: 103, ScaledValue: 2, Value: 2, HasValue: true}, "2 m above ground"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gribs, err := Read(testMessage{ni: 3, nj: 2, fields: []testField{tt.field}}.bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := gribs[0].Surface()
+			if s.First != tt.want {
+				t.Errorf("got first surface %+v, want %+v", s.First, tt.want)
+			}
+			if s.Second.Type != 255 || s.Second.HasValue {
+				t.Errorf("got second surface %+v, want missing", s.Second)
+			}
+			if gribs[0].Level != tt.level {
+				t.Errorf("got level %q, want %q", gribs[0].Level, tt.level)
+			}
+		})
+	}
+}
+
+func TestSurfaceUnknownTemplate(t *testing.T) {
+	g, err := Read(withTemplate(testMessage{ni: 3, nj: 2}.bytes(), 4, 99))
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := FixedSurface{Type: 255}
+	if s := g[0].Surface(); s.First != missing || s.Second != missing {
+		t.Errorf("got %+v, want missing surfaces", s)
+	}
+	if g[0].Level != "no_level" {
+		t.Errorf("got level %q, want no_level", g[0].Level)
+	}
+}
+
+func TestReadConstantField(t *testing.T) {
+	tests := []struct {
+		name   string
+		bitmap []bool
+		want   []float32
+	}{
+		{"no bitmap", nil, []float32{273, 273, 273, 273, 273, 273}},
+		{"bitmap", []bool{true, false, true, true, true, true},
+			[]float32{273, 273, 273, 273, UndefinedValue, 273}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := testMessage{ni: 3, nj: 2, fields: []testField{
+				{surface: 100, bitmap: tt.bitmap, values: []byte{}},
+			}}.bytes()
+			// 0 bits per value, section 7 has no packed octets
+			msg[sectionOffset(msg, 5)+19] = 0
+
+			gribs, err := Read(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range gribs[0].Values {
+				if v.Value != tt.want[i] {
+					t.Errorf("value %d is %v, want %v", i, v.Value, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadDisciplines(t *testing.T) {
+	atmosphere := testMessage{ni: 3, nj: 2}.bytes()
+	ocean := testMessage{discipline: 10, ni: 3, nj: 2}.bytes()
+
+	var data []byte
+	for _, msg := range [][]byte{atmosphere, ocean, atmosphere, ocean} {
+		data = append(data, msg...)
+	}
+
+	gribs, err := Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{0, 10, 0, 10}
+	if len(gribs) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(gribs), len(want))
+	}
+	for i, g := range gribs {
+		if g.Discipline != want[i] {
+			t.Errorf("field %d has discipline %d, want %d", i, g.Discipline, want[i])
+		}
+	}
+}
+
+func TestReadMissingEndMarker(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+	data := append(append([]byte{}, msg...), msg...)
+
+	for cut := 0; cut <= 5; cut++ {
+		d := data[:len(data)-cut]
+
+		_, err := Read(d)
+		if (err != nil) != (cut > 0) {
+			t.Errorf("cut %d: got error %v without option", cut, err)
+		}
+
+		gribs, err := Read(d, WithMissingEndMarker())
+		if cut == 5 {
+			if !errors.Is(err, ErrTruncated) {
+				t.Errorf("cut %d: got %v, want %v", cut, err, ErrTruncated)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("cut %d: %v", cut, err)
+		}
+		if len(gribs) != 2 || len(gribs[1].Values) != 6 {
+			t.Fatalf("cut %d: got %d fields", cut, len(gribs))
+		}
+		if gribs[0].EndMarkerMissing() || gribs[1].EndMarkerMissing() != (cut > 0) {
+			t.Errorf("cut %d: got EndMarkerMissing %v and %v", cut,
+				gribs[0].EndMarkerMissing(), gribs[1].EndMarkerMissing())
+		}
+	}
+}
+
+func TestReadSectionOrder(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+	twoFields := testMessage{ni: 3, nj: 2, fields: []testField{defaultField, defaultField}}.bytes()
+
+	renumber := func(msg []byte, num byte, to byte) []byte {
+		out := append([]byte{}, msg...)
+		out[sectionOffset(out, num)+4] = to
+		return out
+	}
+	noSection67 := dropSection(msg, sectionOffset(msg, 7))
+	noSection67 = dropSection(noSection67, sectionOffset(noSection67, 6))
+	// second section 4 follows first section 7
+	second4 := sectionOffset(twoFields, 7) + int(binary.BigEndian.Uint32(twoFields[sectionOffset(twoFields, 7):]))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no section 3", dropSection(msg, sectionOffset(msg, 3))},
+		{"section 1 repeated", renumber(msg, 3, 1)},
+		{"section 0 number", renumber(msg, 4, 0)},
+		{"section 9", renumber(msg, 4, 9)},
+		{"no section 6 and 7", noSection67},
+		{"next field without section 4", dropSection(twoFields, second4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(tt.data); err == nil {
+				t.Error("Read: expected error")
+			}
+			if _, err := ScanHeaders(bytes.NewReader(tt.data)); err == nil {
+				t.Error("ScanHeaders: expected error")
+			}
+		})
+	}
+
+	if _, err := Read(twoFields); err != nil {
+		t.Errorf("two fields: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package gogrib2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/sdifrance/gogrib2/internal"
+)
+
+// Header is GRIB2 field metadata read without unpacking data values
+type Header struct {
+	Edition     int
+	Discipline  int
+	RefTime     time.Time
+	Name        string
+	Description string
+	Unit        string
+	Level       string
+	Nx          int
+	Ny          int
+	// Offset is position of the message from the start of the stream,
+	// fields of the same message share Offset and Length
+	Offset int64
+	// Length is total message length in bytes as read from section 0
+	Length int64
+}
+
+// ScanHeaders reads GRIB2 messages from r and returns their headers, one per
+// field like Read, so a message with several fields gives several Header.
+// Section 7 data values are never unpacked and grid coordinates are not computed.
+// Of the options only WithMaxMessageSize applies.
+func ScanHeaders(r io.Reader, opts ...Option) ([]Header, error) {
+	o := newOptions(opts)
+
+	headers := []Header{}
+
+	var offset int64
+	for {
+		sec0 := make([]byte, 16)
+		n, err := io.ReadFull(r, sec0)
+		if err == io.EOF {
+			return headers, nil
+		}
+		if isPadding(sec0[:n]) {
+			// skip trailing padding after the last message
+			rest, rerr := ioutil.ReadAll(r)
+			if rerr == nil && isPadding(rest) {
+				return headers, nil
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read section 0 at offset %d: %v: %w", offset, err, ErrTruncated)
+		}
+		if string(sec0[0:4]) != "GRIB" {
+			return nil, fmt.Errorf("Message at offset %d must start with 'GRIB': %w", offset, ErrNotGRIB)
+		}
+		if sec0[7] != 2 {
+			return nil, fmt.Errorf("Message at offset %d has edition %d: %w", offset, sec0[7], ErrUnsupportedEdition)
+		}
+
+		length := int64(binary.BigEndian.Uint64(sec0[8:]))
+		if length < 16+4 {
+			return nil, fmt.Errorf("Message at offset %d has invalid length %d", offset, binary.BigEndian.Uint64(sec0[8:]))
+		}
+		if o.maxMessageSize > 0 && length > int64(o.maxMessageSize) {
+			return nil, fmt.Errorf("Message at offset %d has length %d, larger than maximum %d",
+				offset, length, o.maxMessageSize)
+		}
+
+		// buffer grows as the message is read, so a wrong length
+		// does not allocate memory for data that is not there
+		var buf bytes.Buffer
+		buf.Write(sec0)
+		if _, err := io.CopyN(&buf, r, length-16); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("Failed to read message at offset %d: %v: %w", offset, err, ErrTruncated)
+		}
+		data := buf.Bytes()
+
+		h, err := scanHeader(data, offset)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan message at offset %d: %w", offset, err)
+		}
+		headers = append(headers, h...)
+
+		offset += length
+	}
+}
+
+// ParseIndicatorSection reads section 0 at the start of data and returns
+// edition, total message length in bytes and discipline (code table 0.0).
+// Both the GRIB1 3-byte and the GRIB2 8-byte lengths are handled, discipline
+// is always 0 for GRIB1 which has no such field.
+func ParseIndicatorSection(data []byte) (edition int, length int, discipline int, err error) {
+	if len(data) < 8 {
+		return 0, 0, 0, fmt.Errorf("Section 0 is %d bytes long: %w", len(data), ErrTruncated)
+	}
+	if string(data[0:4]) != "GRIB" {
+		return 0, 0, 0, fmt.Errorf("First 4 bytes must be 'GRIB': %w", ErrNotGRIB)
+	}
+
+	edition = int(data[7])
+	switch edition {
+	case 1:
+		length = int(data[4])<<16 | int(data[5])<<8 | int(data[6])
+	case 2:
+		if len(data) < sectionHeaderLength[0] {
+			return 0, 0, 0, fmt.Errorf("Section 0 is %d bytes long: %w", len(data), ErrTruncated)
+		}
+		l := binary.BigEndian.Uint64(data[8:])
+		if l > uint64(int(^uint(0)>>1)) {
+			return 0, 0, 0, fmt.Errorf("Message length %d is too large", l)
+		}
+		length = int(l)
+		discipline = int(data[6])
+	default:
+		return 0, 0, 0, fmt.Errorf("Edition %d: %w", edition, ErrUnsupportedEdition)
+	}
+	return edition, length, discipline, nil
+}
+
+// scanHeader returns headers of the fields of single GRIB2 message
+// at offset in the stream
+func scanHeader(data []byte, offset int64) ([]Header, error) {
+	headers := []Header{}
+
+	sections := [][]byte{data[0:16], nil, nil, nil, nil, nil, nil, nil}
+
+	end, err := messageEnd(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	end -= 4
+
+	last := 0
+	start := sectionHeaderLength[0]
+	for start < end {
+		size, cur, err := readSectionHeader(data[:end], start)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkSectionOrder(last, cur, start); err != nil {
+			return nil, err
+		}
+		last = cur
+		sections[cur] = data[start : start+size]
+		start += size
+
+		if cur != 7 {
+			continue
+		}
+
+		if err := checkTemplateLength(sections); err != nil {
+			return nil, err
+		}
+
+		h := Header{
+			Edition:    int(data[7]),
+			Discipline: int(data[6]),
+			Offset:     offset,
+			Length:     int64(len(data)),
+		}
+		h.RefTime = internal.RefTime(sections)
+
+		h.Name, h.Description, h.Unit, err = internal.GetInfo(sections)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to GetInfo: %w", err)
+		}
+
+		h.Level, err = internal.GetLevel(sections)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to GetLevel: %w", err)
+		}
+
+		h.Nx, h.Ny, err = internal.GridSize(sections)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get grid size: %w", err)
+		}
+		headers = append(headers, h)
+	}
+	if last != 7 {
+		return nil, fmt.Errorf("Message ends at offset %d after section %d, section 7 is missing", end, last)
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,135 @@
+package gogrib2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestScanHeaders(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+
+	h, err := ScanHeaders(bytes.NewReader(append(append([]byte{}, msg...), msg...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 {
+		t.Fatalf("got %d headers, want 2", len(h))
+	}
+	if h[1].Offset != int64(len(msg)) || h[1].Length != int64(len(msg)) {
+		t.Errorf("second header at %d with length %d, want %d and %d",
+			h[1].Offset, h[1].Length, len(msg), len(msg))
+	}
+	if h[0].Name != "TMP" || h[0].Level != "500 mb" || h[0].Nx != 3 || h[0].Ny != 2 {
+		t.Errorf("got %+v", h[0])
+	}
+}
+
+func TestScanHeadersFields(t *testing.T) {
+	first := testMessage{ni: 3, nj: 2}.bytes()
+	msg := testMessage{ni: 3, nj: 2, fields: []testField{
+		{category: 2, number: 2, surface: 100, surfaceValue: 50000},
+		{category: 2, number: 3, surface: 100, surfaceValue: 50000},
+	}}.bytes()
+
+	h, err := ScanHeaders(bytes.NewReader(append(append([]byte{}, first...), msg...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := Read(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 || len(g) != 2 {
+		t.Fatalf("got %d headers and %d fields, want 3 and 2", len(h), len(g))
+	}
+	for i, want := range []string{"UGRD", "VGRD"} {
+		hh := h[1+i]
+		if hh.Name != want || hh.Name != g[i].Name {
+			t.Errorf("header %d is %s, field is %s, want %s", 1+i, hh.Name, g[i].Name, want)
+		}
+		if hh.Offset != int64(len(first)) || hh.Length != int64(len(msg)) {
+			t.Errorf("header %d at %d with length %d, want %d and %d",
+				1+i, hh.Offset, hh.Length, len(first), len(msg))
+		}
+	}
+}
+
+func TestScanHeadersLength(t *testing.T) {
+	huge := testMessage{ni: 3, nj: 2}.bytes()
+	binary.BigEndian.PutUint64(huge[8:], 1<<50)
+
+	tests := []struct {
+		name string
+		data []byte
+		opts []Option
+		want error
+	}{
+		{"huge length", huge, nil, ErrTruncated},
+		{"negative length", append(huge[:8:8], 0xff, 0, 0, 0, 0, 0, 0, 0), nil, nil},
+		{"truncated", huge[:40], nil, ErrTruncated},
+		{"over maximum", testMessage{ni: 3, nj: 2}.bytes(), []Option{WithMaxMessageSize(100)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ScanHeaders(bytes.NewReader(tt.data), tt.opts...)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIndicatorSection(t *testing.T) {
+	grib2 := testMessage{discipline: 10, ni: 3, nj: 2}.bytes()
+	grib1 := []byte{'G', 'R', 'I', 'B', 0x01, 0x02, 0x03, 1}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		edition    int
+		length     int
+		discipline int
+		err        error
+	}{
+		{"grib1", grib1, 1, 0x010203, 0, nil},
+		{"grib2", grib2, 2, len(grib2), 10, nil},
+		{"grib2 section 0 only", grib2[:16], 2, len(grib2), 10, nil},
+		{"short", grib2[:7], 0, 0, 0, ErrTruncated},
+		{"short grib2", grib2[:12], 0, 0, 0, ErrTruncated},
+		{"bad magic", append([]byte("GRIP"), grib2[4:]...), 0, 0, 0, ErrNotGRIB},
+		{"edition 3", append(append([]byte{}, grib2[:7]...), 3), 0, 0, 0, ErrUnsupportedEdition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edition, length, discipline, err := ParseIndicatorSection(tt.data)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if edition != tt.edition || length != tt.length || discipline != tt.discipline {
+				t.Errorf("got edition %d, length %d, discipline %d, want %d, %d, %d",
+					edition, length, discipline, tt.edition, tt.length, tt.discipline)
+			}
+		})
+	}
+}
+
+func BenchmarkScanHeaders(b *testing.B) {
+	b.SetBytes(int64(len(benchMessage)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ScanHeaders(bytes.NewReader(benchMessage)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -145,7 +145,7 @@ func code_table_4_5a_location(sec [][]unsigned_char) ([]unsigned_char, error) {
 	case 20, 30, 31, 32, 1000, 1001, 1002, 254:
 		return nil, nil
 	default:
-		return nil, fprintf("code_table_4.5a: product definition template #%d not supported\n", pdt)
+		return nil, fatal_error_unsupported(ErrUnsupportedTemplate, "code_table_4.5a: product definition template #%d", pdt)
 	}
 }
 
@@ -191,7 +191,7 @@ func code_table_4_5b_location(sec [][]unsigned_char) ([]unsigned_char, error) {
 	case 20, 30, 31, 32, 1000, 1001, 1002, 254:
 		return nil, nil
 	default:
-		return nil, fprintf("code_table_4.5b: product definition template #%d not supported\n", pdt)
+		return nil, fatal_error_unsupported(ErrUnsupportedTemplate, "code_table_4.5b: product definition template #%d", pdt)
 	}
 }
 
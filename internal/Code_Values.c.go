@@ -1,5 +1,7 @@
 package internal
 
+import "errors"
+
 func stat_proc_verf_time(sec [][]unsigned_char, year *int, month *int, day *int, hour *int, minute *int, second *int) int {
 	var stat_proc_time []unsigned_char
 
@@ -104,40 +106,74 @@ func forecast_time_in_units(sec [][]unsigned_char) int {
 
 func fixed_surfaces(sec [][]unsigned_char, type1 *int, surface1 *float, undef_val1 *int, type2 *int, surface2 *float, undef_val2 *int) error {
 
+	s1, s2, err := fixed_surfaces_scaled(sec)
+	if err != nil {
+		return err
+	}
+	*type1, *surface1, *undef_val1 = s1.type_, float(s1.value), s1.undef_val
+	*type2, *surface2, *undef_val2 = s2.type_, float(s2.value), s2.undef_val
+	return nil
+}
+
+/*
+ * fixed_surface is a fixed surface (code table 4.5), value is
+ * scaled_value * 10^-scale_factor, undef_val is 1 if it is missing
+ */
+type fixed_surface struct {
+	type_        int
+	scale_factor int
+	scaled_value int
+	value        double
+	undef_val    int
+}
+
+/*
+ * fixed_surfaces_scaled is fixed_surfaces with the value in double
+ * and its scale factor and scaled value, both surfaces are missing
+ * for product definition templates without known fixed surfaces
+ */
+func fixed_surfaces_scaled(sec [][]unsigned_char) (s1 fixed_surface, s2 fixed_surface, err error) {
+
 	var p1, p2 []unsigned_char
-	*undef_val1 = 1
-	*undef_val2 = 1
-	*surface1 = UNDEFINED
-	*surface2 = UNDEFINED
-	*type1 = 255
-	*type2 = 255
-
-	p1, err := code_table_4_5a_location(sec)
+	s1 = fixed_surface{type_: 255, value: UNDEFINED, undef_val: 1}
+	s2 = s1
+
+	p1, err = code_table_4_5a_location(sec)
+	if errors.Is(err, ErrUnsupportedTemplate) {
+		return s1, s2, nil
+	}
 	if err != nil {
-		fatal_error_wrap(err, "Failed to execute code_table_4_5a_location")
+		return s1, s2, fatal_error_wrap(err, "Failed to execute code_table_4_5a_location")
 	}
 	p2, err = code_table_4_5b_location(sec)
+	if errors.Is(err, ErrUnsupportedTemplate) {
+		return s1, s2, nil
+	}
 	if err != nil {
-		fatal_error_wrap(err, "Failed to execute code_table_4_5b_location")
+		return s1, s2, fatal_error_wrap(err, "Failed to execute code_table_4_5b_location")
 	}
 
 	if p1 != nil && p1[0] != 255 {
-		*type1 = int(p1[0])
+		s1.type_ = int(p1[0])
 		if p1[1] != 255 {
 			if p1[2] != 255 || p1[3] != 255 || p1[4] != 255 || p1[5] != 255 {
-				*undef_val1 = 0
-				*surface1 = scaled2flt(INT1(p1[1]), int4(p1[2:]))
+				s1.undef_val = 0
+				s1.scale_factor = INT1(p1[1])
+				s1.scaled_value = int4(p1[2:])
+				s1.value = scaled2dbl(s1.scale_factor, s1.scaled_value)
 			}
 		}
 	}
 	if p2 != nil && p2[0] != 255 {
-		*type2 = int(p2[0])
+		s2.type_ = int(p2[0])
 		if p2[1] != 255 {
 			if p2[2] != 255 || p2[3] != 255 || p2[4] != 255 || p2[5] != 255 {
-				*undef_val2 = 0
-				*surface2 = scaled2flt(INT1(p2[1]), int4(p2[2:]))
+				s2.undef_val = 0
+				s2.scale_factor = INT1(p2[1])
+				s2.scaled_value = int4(p2[2:])
+				s2.value = scaled2dbl(s2.scale_factor, s2.scaled_value)
 			}
 		}
 	}
-	return nil
+	return s1, s2, nil
 }
@@ -60,3 +60,55 @@ func radius_earth(sec [][]unsigned_char) (double, error) {
 
 	return radius, nil
 }
+
+/* function to return the major and minor axes of the earth */
+
+func axes_earth(sec [][]unsigned_char) (major double, minor double, err error) {
+	var table_3_2 int
+	var p []unsigned_char
+	var factor, value int
+
+	p = code_table_3_2_location(sec)
+	if p == nil {
+		return 0, 0, fatal_error("axes_earth: code_table 3.2 is unknown", "")
+	}
+
+	table_3_2 = int(p[0])
+
+	switch table_3_2 {
+	case 2:
+		major = 6378160.0
+		minor = 6356775.0
+	case 3, 7:
+		factor = INT1(p[6])
+		value = int4(p[7:])
+		major = scaled2dbl(factor, value)
+		factor = INT1(p[11])
+		value = int4(p[12:])
+		minor = scaled2dbl(factor, value)
+
+		/* axes in km, convert to m */
+		if table_3_2 == 3 {
+			major *= 1000.0
+			minor *= 1000.0
+		}
+	case 4, 5:
+		major = 6378137.0
+		minor = 6356752.314
+	case 9:
+		major = 6377563.396
+		minor = 6356256.909
+	default:
+		/* spherical earth */
+		major, err = radius_earth(sec)
+		if err != nil {
+			return 0, 0, fatal_error_wrap(err, "Failed to execute radius_earth")
+		}
+		minor = major
+	}
+
+	if major < 6300000.0 || major > 6400000.0 || minor < 6300000.0 || minor > major {
+		return 0, 0, fprintf("axes of earth are %.1f m and %.1f m", major, minor)
+	}
+	return major, minor, nil
+}
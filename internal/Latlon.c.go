@@ -59,12 +59,11 @@ func get_latlon(sec [][]unsigned_char, lon *[]double, lat *[]double) error {
 		// TODO: port gauss2ll
 		// gauss2ll(sec, lat, lon)
 	} else if grid_template == 90 {
-		// TODO: port space_view2ll
-		// space_view2ll(sec, lat, lon)
+		return space_view2ll(sec, lat, lon)
 	} else if grid_template == 130 {
 		// TODO: port irr_grid2ll
 		// irr_grid2ll(sec, lat, lon)
 	}
 
-	return fatal_error("Unsupported Grid template: %d", grid_template)
+	return fatal_error_unsupported(ErrUnsupportedGrid, "grid template %d", grid_template)
 }
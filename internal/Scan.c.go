@@ -0,0 +1,51 @@
+package internal
+
+/*
+ * to_we_sn_scan: reorders data from the scan order of flag table 3.4
+ * to we:sn order, the order produced by the *2ll routines
+ *
+ * thinned and staggered grids are left in raw order
+ */
+func to_we_sn_scan(data []float, scan int, npnts unsigned_int, nx int, ny int) error {
+	var i, j, k int
+
+	if scan == -1 || nx < 1 || ny < 1 {
+		return nil
+	}
+	if (scan & 15) != 0 {
+		return nil
+	}
+	if (scan & (128 | 64 | 32 | 16)) == 64 {
+		return nil // already we:sn
+	}
+	if unsigned_int(nx)*unsigned_int(ny) != npnts {
+		return fprintf("to_we_sn_scan: nx*ny is %d, npnts is %d", nx*ny, npnts)
+	}
+
+	tmp := make([]float, npnts)
+	copy(tmp, data)
+
+	for k = 0; k < int(npnts); k++ {
+		if (scan & 32) == 0 { /* adjacent points in i direction are consecutive */
+			i = k % nx
+			j = k / nx
+			if (scan&16) != 0 && (j&1) != 0 {
+				i = nx - 1 - i
+			}
+		} else { /* adjacent points in j direction are consecutive */
+			j = k % ny
+			i = k / ny
+			if (scan&16) != 0 && (i&1) != 0 {
+				j = ny - 1 - j
+			}
+		}
+		if (scan & 128) != 0 {
+			i = nx - 1 - i
+		}
+		if (scan & 64) == 0 {
+			j = ny - 1 - j
+		}
+		data[i+j*nx] = tmp[k]
+	}
+	return nil
+}
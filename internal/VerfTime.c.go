@@ -129,9 +129,15 @@ func verftime(sec [][]unsigned_char, year *int, month *int, day *int, hour *int,
 		return nil
 	}
 
-	if i != 0 && i != 1 {
-		return fprintf("verifying time: Table 1.2=%d not supported using RT=analysis/start of forecast\n", i)
-	}
+	// other values only warn in wgrib2, RT is used as analysis/start of forecast
+	/*
+		if i != 0 && i != 1 {
+			if error_count == 0 {
+				fprintf(stderr, "verifying time: Table 1.2=%d not supported using RT=analysis/start of forecast\n", i)
+				error_count++
+			}
+		}
+	*/
 
 	units = code_table_4_4(sec)
 	dtime = forecast_time_in_units(sec)
@@ -427,7 +427,7 @@ func polar2ll(sec [][]unsigned_char, llat *[]double, llon *[]double) error {
 			}
 	*/
 	*llat = make([]double, nnpnts, nnpnts)
-	*llat = make([]double, nnpnts, nnpnts)
+	*llon = make([]double, nnpnts, nnpnts)
 
 	lat = *llat
 	lon = *llon
@@ -618,9 +618,7 @@ func lambert2ll(sec [][]unsigned_char, llat *[]double, llon *[]double) error {
 		theta = atan(x / tmp)
 		rho = sqrt(x*x + tmp*tmp)
 		//rho = n > 0 ? rho : -rho;
-		if n > 0 {
-			rho = rho
-		} else {
+		if n <= 0 {
 			rho = -rho
 		}
 		lond = lon2d + todegrees(theta/n)
@@ -638,3 +636,122 @@ func lambert2ll(sec [][]unsigned_char, llat *[]double, llon *[]double) error {
 	}
 	return nil
 } /* end lambert2ll() */
+
+/*
+ * space_view2ll: inverse of the normalized geostationary projection
+ * (CGMS LRIT/HRIT Global Specification, 4.4.3.2)
+ *
+ * points that do not see the earth are set to UNDEFINED
+ */
+func space_view2ll(sec [][]unsigned_char, llat *[]double, llon *[]double) error {
+
+	var lat, lon []double
+	var gds []unsigned_char
+
+	var major, minor, lap, lop, orient, nr, h, xp, yp, rx, ry double
+	var x, y, cos_x, sin_x, cos_y, sin_y, factor, sa, sd, sn, s1, s2, s3, sxy double
+	var ix, iy unsigned_int
+	var nnx, nny unsigned_int
+	var nres, nscan int
+	var nnpnts unsigned_int
+
+	var n_variable_dim int
+	var variable_dim, raw_variable_dim []int
+
+	get_nxny_(sec, &nnx, &nny, &nnpnts, &nres, &nscan, &n_variable_dim, &variable_dim, &raw_variable_dim)
+
+	gds = sec[3]
+
+	if nnx < 1 || nny < 1 {
+		return fprintf("Sorry code does not handle variable nx/ny yet")
+	}
+	if nnx*nny != nnpnts {
+		return fprintf("space_view2ll: nx*ny is %d, Sec3 gives %d", nnx*nny, nnpnts)
+	}
+	if GDS_Scan_staggered_storage(nscan) {
+		return fprintf("space_view2ll: staggered grids are not supported")
+	}
+
+	lap = GDS_Space_lap(gds)
+	lop = GDS_Space_lop(gds)
+	orient = GDS_Space_orientation(gds)
+	nr = GDS_Space_altitude(gds)
+
+	if lap != 0.0 {
+		return fprintf("space_view2ll: sub-satellite latitude %g is not supported", lap)
+	}
+	if orient != 0.0 {
+		return fprintf("space_view2ll: grid orientation %g is not supported", orient)
+	}
+	if nr <= 1.0 || GDS_Space_dx(gds) == 0 || GDS_Space_dy(gds) == 0 {
+		return fprintf("space_view2ll: bad camera altitude or apparent earth diameter")
+	}
+
+	major, minor, err := axes_earth(sec)
+	if err != nil {
+		return fatal_error_wrap(err, "Failed to execute axes_earth")
+	}
+
+	/* distance from earth centre to the satellite */
+	h = nr * major
+
+	/* angular size of one grid length, from apparent earth diameter */
+	rx = 2.0 * asin(1.0/nr) / double(GDS_Space_dx(gds))
+	ry = 2.0 * asin(1.0/nr) / double(GDS_Space_dy(gds))
+
+	/* sub-satellite point relative to the first grid point */
+	xp = GDS_Space_xp(gds) - double(GDS_Space_x0(gds))
+	yp = GDS_Space_yp(gds) - double(GDS_Space_y0(gds))
+
+	/* lat-lon should be in a WE:SN order */
+	if !(GDS_Scan_y(nscan)) {
+		yp = double(nny) - 1 - yp
+	}
+	if !(GDS_Scan_x(nscan)) {
+		xp = double(nnx) - 1 - xp
+	}
+
+	*llat = make([]double, nnpnts, nnpnts)
+	*llon = make([]double, nnpnts, nnpnts)
+
+	lat = *llat
+	lon = *llon
+
+	factor = (major * major) / (minor * minor)
+	lop *= (M_PI / 180.0)
+
+	for iy = 0; iy < nny; iy++ {
+		y = (double(iy) - yp) * ry
+		cos_y = cos(y)
+		sin_y = sin(y)
+		for ix = 0; ix < nnx; ix++ {
+			x = (double(ix) - xp) * rx
+			cos_x = cos(x)
+			sin_x = sin(x)
+
+			sa = h * cos_x * cos_y
+			sd = sa*sa - (cos_y*cos_y+factor*sin_y*sin_y)*(h*h-major*major)
+			if sd <= 0.0 {
+				lon[ix+iy*nnx] = UNDEFINED
+				lat[ix+iy*nnx] = UNDEFINED
+				continue
+			}
+			sn = (sa - sqrt(sd)) / (cos_y*cos_y + factor*sin_y*sin_y)
+			s1 = h - sn*cos_x*cos_y
+			s2 = sn * sin_x * cos_y
+			s3 = sn * sin_y
+			sxy = sqrt(s1*s1 + s2*s2)
+
+			tmp := todegrees(atan(s2/s1) + lop)
+			if tmp < 0.0 {
+				tmp += 360.0
+			}
+			if tmp >= 360.0 {
+				tmp -= 360.0
+			}
+			lon[ix+iy*nnx] = tmp
+			lat[ix+iy*nnx] = todegrees(atan(factor * s3 / sxy))
+		}
+	}
+	return nil
+} /* end space_view2ll() */
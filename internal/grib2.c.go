@@ -251,3 +251,53 @@ func GDS_Lambert_Latin2(gds []unsigned_char) double {
 func GDS_Lambert_LatD(gds []unsigned_char) double {
 	return double(int4(gds[47:])) * 0.000001
 }
+
+// #define GDS_Space_lap(gds)		(int4(gds+38) * 0.000001)
+func GDS_Space_lap(gds []unsigned_char) double {
+	return double(int4(gds[38:])) * 0.000001
+}
+
+// #define GDS_Space_lop(gds)		(int4(gds+42) * 0.000001)
+func GDS_Space_lop(gds []unsigned_char) double {
+	return double(int4(gds[42:])) * 0.000001
+}
+
+// #define GDS_Space_dx(gds)		uint4(gds+47)
+func GDS_Space_dx(gds []unsigned_char) unsigned_int {
+	return uint4(gds[47:])
+}
+
+// #define GDS_Space_dy(gds)		uint4(gds+51)
+func GDS_Space_dy(gds []unsigned_char) unsigned_int {
+	return uint4(gds[51:])
+}
+
+// #define GDS_Space_xp(gds)		(uint4(gds+55) * 0.001)
+func GDS_Space_xp(gds []unsigned_char) double {
+	return double(uint4(gds[55:])) * 0.001
+}
+
+// #define GDS_Space_yp(gds)		(uint4(gds+59) * 0.001)
+func GDS_Space_yp(gds []unsigned_char) double {
+	return double(uint4(gds[59:])) * 0.001
+}
+
+// #define GDS_Space_orientation(gds)	(int4(gds+64) * 0.000001)
+func GDS_Space_orientation(gds []unsigned_char) double {
+	return double(int4(gds[64:])) * 0.000001
+}
+
+// #define GDS_Space_altitude(gds)	(uint4(gds+68) * 0.000001)
+func GDS_Space_altitude(gds []unsigned_char) double {
+	return double(uint4(gds[68:])) * 0.000001
+}
+
+// #define GDS_Space_x0(gds)		uint4(gds+72)
+func GDS_Space_x0(gds []unsigned_char) unsigned_int {
+	return uint4(gds[72:])
+}
+
+// #define GDS_Space_y0(gds)		uint4(gds+76)
+func GDS_Space_y0(gds []unsigned_char) unsigned_int {
+	return uint4(gds[76:])
+}
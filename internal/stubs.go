@@ -1,12 +1,11 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"time"
 	"unsafe"
-
-	"github.com/pkg/errors"
 )
 
 type unsigned_char byte
@@ -99,24 +98,60 @@ func atan(v double) double {
 	return double(math.Atan(float64(v)))
 }
 
+// dummy_args drops the trailing "" that ported wgrib2 calls pass
+// when the format has no verb for it
+func dummy_args(args []interface{}) []interface{} {
+	for len(args) > 0 && args[len(args)-1] == "" {
+		args = args[:len(args)-1]
+	}
+	return args
+}
+
 func fatal_error(format string, args ...interface{}) error {
-	return errors.Errorf(format, args)
+	return fmt.Errorf(format, dummy_args(args)...)
 }
 
 func fatal_error_ii(format string, args ...interface{}) error {
-	return errors.Errorf(format, args)
+	return fmt.Errorf(format, dummy_args(args)...)
 }
 
 func fatal_error_i(format string, args ...interface{}) error {
-	return errors.Errorf(format, args)
+	return fmt.Errorf(format, dummy_args(args)...)
 }
 
 func fatal_error_wrap(err error, format string, args ...interface{}) error {
-	return errors.Wrapf(err, format, args)
+	return fmt.Errorf(format+": %w", append(dummy_args(args), err)...)
+}
+
+// ErrUnsupportedTemplate is returned for grid definition, product definition
+// and data representation templates that are not implemented
+var ErrUnsupportedTemplate = errors.New("unsupported template")
+
+// ErrUnsupportedGrid and ErrUnsupportedPacking narrow ErrUnsupportedTemplate
+// to grid definition and data representation templates
+var (
+	ErrUnsupportedGrid    error = &templateError{"unsupported grid"}
+	ErrUnsupportedPacking error = &templateError{"unsupported packing"}
+)
+
+type templateError struct {
+	msg string
+}
+
+func (e *templateError) Error() string {
+	return e.msg
+}
+
+func (e *templateError) Unwrap() error {
+	return ErrUnsupportedTemplate
+}
+
+func fatal_error_unsupported(kind error, format string, args ...interface{}) error {
+	return fmt.Errorf(format+": %w", append(args, kind)...)
 }
 
 func fprintf(format string, args ...interface{}) error {
-	return errors.Errorf(format, args...)
+	return fmt.Errorf(format, args...)
 }
 
 func sprintf(format string, args ...interface{}) string {
@@ -141,6 +176,18 @@ func UnpackData(sec [][]byte) ([]float32, error) {
 		return nil, err
 	}
 
+	var nx, ny, res, scan, n_variable_dim int
+	var npnts unsigned_int
+	var variable_dim, raw_variable_dim []int
+	err = get_nxny(g_sec, &nx, &ny, &npnts, &res, &scan, &n_variable_dim, &variable_dim, &raw_variable_dim)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to execute get_nxny: %w", err)
+	}
+	err = to_we_sn_scan(g_data, scan, ndata, nx, ny)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to execute to_we_sn_scan: %w", err)
+	}
+
 	return *(*[]float32)(unsafe.Pointer(&g_data)), nil
 }
 
@@ -153,6 +200,12 @@ func RefTime(sec [][]byte) time.Time {
 	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
 }
 
+// RefTimeSignificance returns significance of reference time (code table 1.2)
+func RefTimeSignificance(sec [][]byte) int {
+	g_sec := *(*[][]unsigned_char)(unsafe.Pointer(&sec))
+	return code_table_1_2(g_sec)
+}
+
 func VerfTime(sec [][]byte) (time.Time, error) {
 	var year, month, day, hour, minute, second int
 
@@ -160,7 +213,7 @@ func VerfTime(sec [][]byte) (time.Time, error) {
 
 	err := verftime(g_sec, &year, &month, &day, &hour, &minute, &second)
 	if err != nil {
-		return time.Now(), errors.Wrapf(err, "Failed to run verftime")
+		return time.Now(), fmt.Errorf("Failed to run verftime: %w", err)
 	}
 
 	return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC), nil
@@ -171,7 +224,7 @@ func GetInfo(sec [][]byte) (name string, desc string, unit string, err error) {
 
 	err = getName(g_sec, &name, &desc, &unit)
 	if err != nil {
-		return "", "", "", errors.Wrapf(err, "Failed to execute getName")
+		return "", "", "", fmt.Errorf("Failed to execute getName: %w", err)
 	}
 	return name, desc, unit, nil
 }
@@ -181,7 +234,118 @@ func GetLevel(sec [][]byte) (level string, err error) {
 
 	err = f_lev(g_sec, &level)
 	if err != nil {
-		return "", errors.Wrapf(err, "Failed to execute f_lev")
+		return "", fmt.Errorf("Failed to execute f_lev: %w", err)
 	}
 	return level, nil
 }
+
+// Surface is fixed surface of section 4 (code table 4.5).
+// Type is 255 when surface is missing, HasValue is false when its value is missing.
+type Surface struct {
+	Type        int
+	ScaleFactor int
+	ScaledValue int
+	Value       float64
+	HasValue    bool
+}
+
+// FixedSurfaces returns first and second fixed surfaces of the product
+func FixedSurfaces(sec [][]byte) (first Surface, second Surface, err error) {
+	g_sec := *(*[][]unsigned_char)(unsafe.Pointer(&sec))
+
+	s1, s2, err := fixed_surfaces_scaled(g_sec)
+	if err != nil {
+		return first, second, fmt.Errorf("Failed to execute fixed_surfaces_scaled: %w", err)
+	}
+	return s1.export(), s2.export(), nil
+}
+
+func (s fixed_surface) export() Surface {
+	e := Surface{Type: s.type_}
+	if s.undef_val == 0 {
+		e.ScaleFactor = s.scale_factor
+		e.ScaledValue = s.scaled_value
+		e.Value = float64(s.value)
+		e.HasValue = true
+	}
+	return e
+}
+
+func GridSize(sec [][]byte) (nx int, ny int, err error) {
+	var npnts unsigned_int
+	var res, scan, n_variable_dim int
+	var variable_dim, raw_variable_dim []int
+
+	g_sec := *(*[][]unsigned_char)(unsafe.Pointer(&sec))
+
+	err = get_nxny(g_sec, &nx, &ny, &npnts, &res, &scan, &n_variable_dim, &variable_dim, &raw_variable_dim)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed to execute get_nxny: %w", err)
+	}
+	return nx, ny, nil
+}
+
+// Grid is section 3 grid definition of regular grids.
+// Coordinates are in degrees, increments are in degrees for latitude-longitude
+// grids and in meters for projected grids. Fields that grid template does not
+// define are zero.
+type Grid struct {
+	Template int
+	Nx       int
+	Ny       int
+	Scan     int
+	Lat1     float64
+	Lon1     float64
+	Lat2     float64
+	Lon2     float64
+	Dx       float64
+	Dy       float64
+}
+
+func GridInfo(sec [][]byte) (g Grid, err error) {
+	g.Nx, g.Ny, err = GridSize(sec)
+	if err != nil {
+		return g, err
+	}
+
+	g_sec := *(*[][]unsigned_char)(unsafe.Pointer(&sec))
+	gds := g_sec[3]
+
+	g.Template = code_table_3_1(g_sec)
+	g.Scan = flag_table_3_4(g_sec)
+
+	switch g.Template {
+	case 0, 1, 40:
+		units := double(0.000001)
+		if basic_ang := GDS_LatLon_basic_ang(gds); basic_ang != 0 {
+			units = double(basic_ang) / double(GDS_LatLon_sub_ang(gds))
+		}
+		g.Lat1 = float64(double(GDS_LatLon_lat1(gds)) * units)
+		g.Lon1 = float64(double(GDS_LatLon_lon1(gds)) * units)
+		g.Lat2 = float64(double(GDS_LatLon_lat2(gds)) * units)
+		g.Lon2 = float64(double(GDS_LatLon_lon2(gds)) * units)
+		g.Dx = float64(double(GDS_LatLon_dlon(gds)) * units)
+		if g.Template != 40 {
+			// gaussian grids store number of parallels instead of Dj
+			g.Dy = float64(double(GDS_LatLon_dlat(gds)) * units)
+		}
+	case 10:
+		g.Lat1 = float64(GDS_Mercator_lat1(gds))
+		g.Lon1 = float64(GDS_Mercator_lon1(gds))
+		g.Lat2 = float64(GDS_Mercator_lat2(gds))
+		g.Lon2 = float64(GDS_Mercator_lon2(gds))
+		g.Dx = float64(GDS_Mercator_dx(gds))
+		g.Dy = float64(GDS_Mercator_dy(gds))
+	case 20:
+		g.Lat1 = float64(GDS_Polar_lat1(gds))
+		g.Lon1 = float64(GDS_Polar_lon1(gds))
+		g.Dx = float64(GDS_Polar_dx(gds))
+		g.Dy = float64(GDS_Polar_dy(gds))
+	case 30:
+		g.Lat1 = float64(GDS_Lambert_La1(gds))
+		g.Lon1 = float64(GDS_Lambert_Lo1(gds))
+		g.Dx = float64(GDS_Lambert_dx(gds))
+		g.Dy = float64(GDS_Lambert_dy(gds))
+	}
+	return g, nil
+}
@@ -30,9 +30,19 @@ func unpk_grib(sec [][]unsigned_char, data []float) error {
 		return fatal_error("unknown bitmap", "")
 	}
 
+	if err := check_ndata(sec, ndata, bitmap_flag); err != nil {
+		return err
+	}
+
 	if packing == 4 { // ieee
 		if sec[5][11] != 1 {
-			return fatal_error_i("unpk ieee grib file precision %d not supported", int(sec[5][11]))
+			return fatal_error_unsupported(ErrUnsupportedPacking, "unpk ieee grib file precision %d", int(sec[5][11]))
+		}
+
+		npacked := int(uint4(sec[5][5:]))
+		if len(sec[7])-5 < npacked*4 {
+			return fprintf("section 7 has %d octets, %d ieee values need %d",
+				len(sec[7])-5, npacked, npacked*4)
 		}
 
 		// ieee depacking -- simple no bitmap
@@ -119,8 +129,19 @@ func unpk_grib(sec [][]unsigned_char, data []float) error {
 			mask_pointer = sec[6][6:]
 		}
 
-		unpk_0(data, sec[7][5:], mask_pointer, nbits, ndata, reference,
+		if nbits > 0 {
+			npacked := int(uint4(sec[5][5:]))
+			if (len(sec[7])-5)*8 < npacked*nbits {
+				return fprintf("section 7 has %d octets, %d values of %d bits do not fit",
+					len(sec[7])-5, npacked, nbits)
+			}
+		}
+
+		err := unpk_0(data, sec[7][5:], mask_pointer, nbits, ndata, reference,
 			bin_scale, dec_scale)
+		if err != nil {
+			return fatal_error_wrap(err, "Failed to execute unpk_0")
+		}
 
 		if packing == 61 { // remove log prescaling
 			// #pragma omp parallel for private(ii) schedule(static)
@@ -132,13 +153,46 @@ func unpk_grib(sec [][]unsigned_char, data []float) error {
 		}
 		return nil
 	} else if packing == 2 || packing == 3 { // complex
-		return fatal_error("unpk_complex is not supported")
+		return fatal_error_unsupported(ErrUnsupportedPacking, "unpk_complex: packing type %d", packing)
 		// TODO: unpk_complex
 		// return unpk_complex(sec, data, ndata)
 	} else if packing == 200 { // run length
-		return fatal_error("unpk_run_length is not supported")
+		return fatal_error_unsupported(ErrUnsupportedPacking, "unpk_run_length: packing type %d", packing)
 		// TODO: unpk_run_length
 		// return unpk_run_length(sec, data, ndata)
 	}
-	return fatal_error_i("packing type %d not supported", packing)
+	return fatal_error_unsupported(ErrUnsupportedPacking, "packing type %d", packing)
+}
+
+// PointCountError is returned when the number of packed values in section 5
+// disagrees with the number of grid points defined by sections 3 and 6
+type PointCountError struct {
+	Expected int
+	Actual   int
+}
+
+func (e *PointCountError) Error() string {
+	return sprintf("number of packed values is %d, grid and bitmap expect %d", e.Actual, e.Expected)
+}
+
+/*
+ * check_ndata compares the number of packed values (section 5) with
+ * the number of grid points (section 3) less the points masked by
+ * the bitmap (section 6)
+ */
+func check_ndata(sec [][]unsigned_char, npnts unsigned_int, bitmap_flag int) error {
+	expected := npnts
+	if bitmap_flag == 0 || bitmap_flag == 254 {
+		if unsigned_int(len(sec[6])-6) < (npnts+7)/8 {
+			return fprintf("bitmap has %d octets, %d grid points need %d",
+				len(sec[6])-6, npnts, (npnts+7)/8)
+		}
+		expected -= missing_points(sec[6][6:], npnts)
+	}
+
+	actual := uint4(sec[5][5:])
+	if actual != expected {
+		return &PointCountError{Expected: int(expected), Actual: int(actual)}
+	}
+	return nil
 }
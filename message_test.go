@@ -0,0 +1,320 @@
+package gogrib2
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// testMessage is synthetic GRIB2 message used in tests: regular lat-lon
+// grid (template 3.0) from 50N 10E with 1 degree increments, and fields of
+// analysis at a horizontal level (template 4.0)
+type testMessage struct {
+	discipline byte
+	ni, nj     int
+	scan       byte
+	// sec3 replaces body of section 3 if not nil
+	sec3   []byte
+	fields []testField
+}
+
+// testField is single field of testMessage. Values are 8-bit simple packed
+// (template 5.0) with reference 273, or IEEE 32-bit (template 5.4)
+type testField struct {
+	category, number byte
+	surface          byte
+	surfaceScale     byte
+	surfaceValue     uint32
+	ieee             bool
+	// bitmap is nil for no bitmap
+	bitmap []bool
+	// reuseBitmap sets bitmap indicator 254, bitmap still tells
+	// which points are packed
+	reuseBitmap bool
+	// values are packed values, 0, 1, 2... if nil
+	values []byte
+}
+
+// defaultField is temperature at 500 hPa
+var defaultField = testField{surface: 100, surfaceValue: 50000}
+
+func u16(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	return b
+}
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// s32 returns v as GRIB sign and magnitude integer
+func s32(v int32) uint32 {
+	if v < 0 {
+		return uint32(-v) | 0x80000000
+	}
+	return uint32(v)
+}
+
+func testSection(num byte, body []byte) []byte {
+	b := u32(uint32(5 + len(body)))
+	b = append(b, num)
+	return append(b, body...)
+}
+
+func (m testMessage) bytes() []byte {
+	npts := m.ni * m.nj
+	fields := m.fields
+	if fields == nil {
+		fields = []testField{defaultField}
+	}
+
+	s1 := []byte{}
+	s1 = append(s1, u16(7)...)
+	s1 = append(s1, u16(0)...)
+	s1 = append(s1, 2, 1, 0)
+	s1 = append(s1, u16(2020)...)
+	s1 = append(s1, 1, 2, 12, 0, 0, 0, 1)
+	body := testSection(1, s1)
+
+	s3 := m.sec3
+	if s3 == nil {
+		s3 = latLonSection(m.ni, m.nj, m.scan, 50000000, 10000000, 1000000)
+	}
+	body = append(body, testSection(3, s3)...)
+
+	for _, f := range fields {
+		s4 := []byte{}
+		s4 = append(s4, u16(0)...)
+		s4 = append(s4, u16(0)...)
+		s4 = append(s4, f.category, f.number, 2, 0, 96)
+		s4 = append(s4, u16(0)...)
+		s4 = append(s4, 0, 1)
+		s4 = append(s4, u32(0)...)
+		s4 = append(s4, f.surface, f.surfaceScale)
+		s4 = append(s4, u32(f.surfaceValue)...)
+		s4 = append(s4, 255, 0)
+		s4 = append(s4, u32(0)...)
+		body = append(body, testSection(4, s4)...)
+
+		ndata := npts
+		if f.bitmap != nil {
+			ndata = 0
+			for _, b := range f.bitmap {
+				if b {
+					ndata++
+				}
+			}
+		}
+		values := f.values
+		if values == nil {
+			for i := 0; i < ndata; i++ {
+				values = append(values, byte(i))
+			}
+		}
+
+		s5 := u32(uint32(ndata))
+		var s7 []byte
+		if f.ieee {
+			s5 = append(s5, u16(4)...)
+			s5 = append(s5, 1)
+			for _, v := range values {
+				s7 = append(s7, u32(math.Float32bits(273+float32(v)))...)
+			}
+		} else {
+			s5 = append(s5, u16(0)...)
+			s5 = append(s5, u32(math.Float32bits(273))...)
+			s5 = append(s5, u16(0)...)
+			s5 = append(s5, u16(0)...)
+			s5 = append(s5, 8, 0)
+			s7 = values
+		}
+		body = append(body, testSection(5, s5)...)
+
+		switch {
+		case f.reuseBitmap:
+			body = append(body, testSection(6, []byte{254})...)
+		case f.bitmap != nil:
+			bm := make([]byte, 1+(npts+7)/8)
+			for i, b := range f.bitmap {
+				if b {
+					bm[1+i/8] |= 128 >> uint(i%8)
+				}
+			}
+			body = append(body, testSection(6, bm)...)
+		default:
+			body = append(body, testSection(6, []byte{255})...)
+		}
+
+		body = append(body, testSection(7, s7)...)
+	}
+
+	s0 := []byte("GRIB")
+	s0 = append(s0, 0, 0, m.discipline, 2)
+	s0 = append(s0, u32(0)...)
+	s0 = append(s0, u32(uint32(16+len(body)+4))...)
+	msg := append(s0, body...)
+	return append(msg, "7777"...)
+}
+
+// latLonSection returns body of section 3 with regular lat-lon grid
+// (template 3.0) of ni by nj points, from the north-west point la1, lo1 with
+// increment inc, all in microdegrees
+func latLonSection(ni, nj int, scan byte, la1, lo1, inc int32) []byte {
+	lat1, lat2 := s32(la1), s32(la1-int32(nj-1)*inc)
+	if scan&64 != 0 {
+		lat1, lat2 = lat2, lat1
+	}
+	lon1, lon2 := s32(lo1), s32(lo1+int32(ni-1)*inc)
+	if scan&128 != 0 {
+		lon1, lon2 = lon2, lon1
+	}
+	s3 := []byte{0}
+	s3 = append(s3, u32(uint32(ni*nj))...)
+	s3 = append(s3, 0, 0)
+	s3 = append(s3, u16(0)...)
+	s3 = append(s3, 6, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(uint32(ni))...)
+	s3 = append(s3, u32(uint32(nj))...)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(0xffffffff)...)
+	s3 = append(s3, u32(lat1)...)
+	s3 = append(s3, u32(lon1)...)
+	s3 = append(s3, 48)
+	s3 = append(s3, u32(lat2)...)
+	s3 = append(s3, u32(lon2)...)
+	s3 = append(s3, u32(uint32(inc))...)
+	s3 = append(s3, u32(uint32(inc))...)
+	s3 = append(s3, scan)
+	return s3
+}
+
+// sectionOffset returns offset of first section num of msg
+func sectionOffset(msg []byte, num byte) int {
+	for start := 16; start < len(msg)-4; {
+		if msg[start+4] == num {
+			return start
+		}
+		start += int(binary.BigEndian.Uint32(msg[start:]))
+	}
+	panic("section not found")
+}
+
+// shortenSection cuts first section num of msg to length octets
+func shortenSection(msg []byte, num byte, length int) []byte {
+	start := sectionOffset(msg, num)
+	size := int(binary.BigEndian.Uint32(msg[start:]))
+	out := append([]byte{}, msg[:start+length]...)
+	out = append(out, msg[start+size:]...)
+	binary.BigEndian.PutUint32(out[start:], uint32(length))
+	binary.BigEndian.PutUint64(out[8:], uint64(len(out)))
+	return out
+}
+
+// dropSection removes section starting at offset start of msg
+func dropSection(msg []byte, start int) []byte {
+	size := int(binary.BigEndian.Uint32(msg[start:]))
+	out := append([]byte{}, msg[:start]...)
+	out = append(out, msg[start+size:]...)
+	binary.BigEndian.PutUint64(out[8:], uint64(len(out)))
+	return out
+}
+
+// polarSection returns body of section 3 with polar stereographic grid
+// (template 3.20) of ni by nj 50 km points from 60N 250E, LoV 255E
+func polarSection(ni, nj int) []byte {
+	s3 := []byte{0}
+	s3 = append(s3, u32(uint32(ni*nj))...)
+	s3 = append(s3, 0, 0)
+	s3 = append(s3, u16(20)...)
+	s3 = append(s3, 6, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(uint32(ni))...)
+	s3 = append(s3, u32(uint32(nj))...)
+	s3 = append(s3, u32(60000000)...)
+	s3 = append(s3, u32(250000000)...)
+	s3 = append(s3, 48)
+	s3 = append(s3, u32(60000000)...)
+	s3 = append(s3, u32(255000000)...)
+	s3 = append(s3, u32(50000000)...)
+	s3 = append(s3, u32(50000000)...)
+	s3 = append(s3, 0, 64)
+	return s3
+}
+
+// mercatorSection returns body of section 3 with Mercator grid (template
+// 3.10) of ni by nj 111.2 km points, about 1 degree, from 0N 10E
+func mercatorSection(ni, nj int) []byte {
+	s3 := []byte{0}
+	s3 = append(s3, u32(uint32(ni*nj))...)
+	s3 = append(s3, 0, 0)
+	s3 = append(s3, u16(10)...)
+	s3 = append(s3, 6, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(uint32(ni))...)
+	s3 = append(s3, u32(uint32(nj))...)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(10000000)...)
+	s3 = append(s3, 48)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(uint32(nj-1)*1000000)...)
+	s3 = append(s3, u32(uint32(10+ni-1)*1000000)...)
+	s3 = append(s3, 64)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(111200000)...)
+	s3 = append(s3, u32(111200000)...)
+	return s3
+}
+
+// withTemplate returns copy of msg with template number of first section
+// num set to template
+func withTemplate(msg []byte, num byte, template uint16) []byte {
+	out := append([]byte{}, msg...)
+	binary.BigEndian.PutUint16(out[sectionOffset(out, num)+templateOffset[num]:], template)
+	return out
+}
+
+// spaceViewSection returns body of section 3 with space view grid (template
+// 3.90) of n by n pixels seen from geostationary altitude over 0N lop, the
+// earth disk is diameter pixels wide and centred on pixel (n/2, n/2)
+func spaceViewSection(n int, lop int32, diameter uint32) []byte {
+	s3 := []byte{0}
+	s3 = append(s3, u32(uint32(n*n))...)
+	s3 = append(s3, 0, 0)
+	s3 = append(s3, u16(90)...)
+	s3 = append(s3, 6, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, 0)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(uint32(n))...)
+	s3 = append(s3, u32(uint32(n))...)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(s32(lop))...)
+	s3 = append(s3, 48)
+	s3 = append(s3, u32(diameter)...)
+	s3 = append(s3, u32(diameter)...)
+	s3 = append(s3, u32(uint32(n/2*1000))...)
+	s3 = append(s3, u32(uint32(n/2*1000))...)
+	s3 = append(s3, 64)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(6610700)...)
+	s3 = append(s3, u32(0)...)
+	s3 = append(s3, u32(0)...)
+	return s3
+}
@@ -0,0 +1,50 @@
+package gogrib2
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// mutatedCorpus returns copies of msg with every octet replaced by 0, 255,
+// its value plus one and minus one, and msg cut at every length. Go 1.15
+// has no native fuzzing, this fixed corpus covers the same kind of input
+func mutatedCorpus(msg []byte) map[string][]byte {
+	corpus := map[string][]byte{}
+	for i := range msg {
+		for _, v := range []byte{0, 255, msg[i] + 1, msg[i] - 1} {
+			m := append([]byte{}, msg...)
+			m[i] = v
+			corpus[fmt.Sprintf("octet %d is %d", i, v)] = m
+		}
+		corpus[fmt.Sprintf("cut at %d", i)] = msg[:i]
+	}
+	return corpus
+}
+
+func TestReadMutated(t *testing.T) {
+	bitmap := []bool{true, true, true, true, false, true}
+	messages := map[string][]byte{
+		"simple": testMessage{ni: 3, nj: 2}.bytes(),
+		"bitmap": testMessage{ni: 3, nj: 2, fields: []testField{
+			{surface: 100, bitmap: bitmap},
+			{surface: 100, ieee: true, bitmap: bitmap, reuseBitmap: true},
+		}}.bytes(),
+		"polar":      testMessage{ni: 3, nj: 2, sec3: polarSection(3, 2)}.bytes(),
+		"space view": testMessage{ni: 3, nj: 3, sec3: spaceViewSection(3, 0, 2)}.bytes(),
+	}
+	for name, msg := range messages {
+		for mutation, data := range mutatedCorpus(msg) {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("%s, %s: panic: %v", name, mutation, r)
+					}
+				}()
+				Read(data)
+				Read(data, WithMissingEndMarker())
+				ScanHeaders(bytes.NewReader(data))
+			}()
+		}
+	}
+}
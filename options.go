@@ -0,0 +1,87 @@
+package gogrib2
+
+import (
+	"math"
+
+	"github.com/sdifrance/gogrib2/internal"
+)
+
+// Option configures Read and ReadContext
+type Option func(*options)
+
+// LongitudeConvention is range of longitudes returned in Value
+type LongitudeConvention int
+
+const (
+	// Longitude0To360 returns longitudes in [0, 360), as computed from the grid
+	Longitude0To360 LongitudeConvention = iota
+	// LongitudeMinus180To180 returns longitudes in [-180, 180)
+	LongitudeMinus180To180
+)
+
+type options struct {
+	missingValue    float32
+	hasMissingValue bool
+	maxMessageSize  int
+	lngConvention   LongitudeConvention
+	allowCutEnd     bool
+}
+
+// WithMissingValue makes Read return NaN for every value equal to v.
+// Points masked by the bitmap are returned as NaN as well, so both kinds of
+// missing data look the same to the caller.
+func WithMissingValue(v float32) Option {
+	return func(o *options) {
+		o.missingValue = v
+		o.hasMissingValue = true
+	}
+}
+
+// WithMaxMessageSize makes Read fail on messages whose section 0 length is
+// larger than n bytes
+func WithMaxMessageSize(n int) Option {
+	return func(o *options) {
+		o.maxMessageSize = n
+	}
+}
+
+// WithLongitudeConvention sets range of longitudes returned in Value
+func WithLongitudeConvention(c LongitudeConvention) Option {
+	return func(o *options) {
+		o.lngConvention = c
+	}
+}
+
+// WithMissingEndMarker makes Read accept a last message whose '7777' end
+// marker is cut off, as in slightly truncated downloads, as long as all its
+// sections are complete. GRIB2.EndMarkerMissing reports such messages.
+func WithMissingEndMarker() Option {
+	return func(o *options) {
+		o.allowCutEnd = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) value(v float32) float32 {
+	if !o.hasMissingValue {
+		return v
+	}
+	if v == o.missingValue || (v > internal.UNDEFINED_LOW && v < internal.UNDEFINED_HIGH) {
+		return float32(math.NaN())
+	}
+	return v
+}
+
+func (o *options) longitude(lon float64) float64 {
+	if o.lngConvention == LongitudeMinus180To180 && lon >= 180 && lon < 360 {
+		return lon - 360
+	}
+	return lon
+}
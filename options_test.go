@@ -0,0 +1,92 @@
+package gogrib2
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestWithMissingValue(t *testing.T) {
+	// point 1 is masked by the bitmap, packed values 0 to 4 are 273 to 277
+	msg := testMessage{ni: 3, nj: 2, fields: []testField{
+		{surface: 100, bitmap: []bool{true, false, true, true, true, true}},
+	}}.bytes()
+
+	g, err := Read(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Read(msg, WithMissingValue(275))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, v := range m[0].Values {
+		plain := g[0].Values[i].Value
+		missing := plain == 275 || plain == UndefinedValue
+		if missing != math.IsNaN(float64(v.Value)) || !missing && v.Value != plain {
+			t.Errorf("point %d is %v with WithMissingValue, %v without", i, v.Value, plain)
+		}
+	}
+	nan := 0
+	for _, v := range m[0].Values {
+		if math.IsNaN(float64(v.Value)) {
+			nan++
+		}
+	}
+	if nan != 2 {
+		t.Errorf("got %d NaN values, want 2", nan)
+	}
+}
+
+// withLongitudes returns copy of msg with first and last longitudes of its
+// template 3.0 grid set to lo1 and lo2 degrees
+func withLongitudes(msg []byte, lo1, lo2 uint32) []byte {
+	out := append([]byte{}, msg...)
+	start := sectionOffset(out, 3)
+	binary.BigEndian.PutUint32(out[start+50:], lo1*1000000)
+	binary.BigEndian.PutUint32(out[start+59:], lo2*1000000)
+	return out
+}
+
+func TestWithLongitudeConvention(t *testing.T) {
+	msg := withLongitudes(testMessage{ni: 5, nj: 2}.bytes(), 178, 182)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []float64
+	}{
+		{"default", nil, []float64{178, 179, 180, 181, 182}},
+		{"0 to 360", []Option{WithLongitudeConvention(Longitude0To360)}, []float64{178, 179, 180, 181, 182}},
+		{"-180 to 180", []Option{WithLongitudeConvention(LongitudeMinus180To180)}, []float64{178, 179, -180, -179, -178}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Read(msg, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.want {
+				if got := g[0].Values[i].Longitude; math.Abs(got-want) > 1e-6 {
+					t.Errorf("longitude %d is %v, want %v", i, got, want)
+				}
+			}
+			grid := g[0].Grid()
+			if grid.FirstLon != tt.want[0] || grid.LastLon != tt.want[4] {
+				t.Errorf("grid from %v to %v, want %v to %v", grid.FirstLon, grid.LastLon, tt.want[0], tt.want[4])
+			}
+		})
+	}
+}
+
+func TestWithMaxMessageSize(t *testing.T) {
+	msg := testMessage{ni: 3, nj: 2}.bytes()
+
+	if _, err := Read(msg, WithMaxMessageSize(len(msg))); err != nil {
+		t.Errorf("message at the limit: %v", err)
+	}
+	if _, err := Read(msg, WithMaxMessageSize(len(msg)-1)); err == nil {
+		t.Error("message above the limit: expected error")
+	}
+}